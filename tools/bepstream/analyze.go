@@ -0,0 +1,404 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+// actionSpan is the wall-clock interval of one ActionExecuted event,
+// attributed to the target that owns it.
+type actionSpan struct {
+	mnemonic string
+	start    time.Time
+	end      time.Time
+}
+
+func (s actionSpan) duration() time.Duration {
+	if s.start.IsZero() || s.end.IsZero() {
+		return 0
+	}
+	return s.end.Sub(s.start)
+}
+
+// targetNode is one vertex of the dependency graph analyze builds up from
+// TargetConfigured/TargetCompleted events: label, the dependencies it was
+// configured with, and the actions it ran.
+type targetNode struct {
+	label   string
+	deps    []string
+	actions []actionSpan
+
+	hasFinish    bool
+	finishTime   time.Time
+	criticalPred string
+}
+
+// depGraph accumulates targetNodes as events stream by, so the critical
+// path and slowest actions can be computed once the stream is exhausted
+// (or at any point along the way).
+type depGraph struct {
+	nodes map[string]*targetNode
+
+	outputFiles int
+}
+
+func newDepGraph() *depGraph {
+	return &depGraph{
+		nodes: make(map[string]*targetNode),
+	}
+}
+
+func (g *depGraph) node(label string) *targetNode {
+	n, ok := g.nodes[label]
+	if !ok {
+		n = &targetNode{label: label}
+		g.nodes[label] = n
+	}
+	return n
+}
+
+// Update folds one BuildEvent into the graph. It's safe to call as events
+// arrive from a live stream or after the fact from a fully-read file.
+func (g *depGraph) Update(event *bespb.BuildEvent) {
+	switch p := event.Payload.(type) {
+	case *bespb.BuildEvent_Configured:
+		label := getTargetLabel(event.Id)
+		n := g.node(label)
+		for _, child := range event.Children {
+			if dep := getTargetLabel(child); dep != "<unknown>" && dep != label {
+				n.deps = append(n.deps, dep)
+			}
+		}
+
+	case *bespb.BuildEvent_Action:
+		a := p.Action
+		if a.Label == "" {
+			break
+		}
+		span := actionSpan{mnemonic: a.Type}
+		if a.StartTime != nil {
+			span.start = a.StartTime.AsTime()
+		}
+		if a.EndTime != nil {
+			span.end = a.EndTime.AsTime()
+		}
+		g.node(a.Label).actions = append(g.node(a.Label).actions, span)
+
+	case *bespb.BuildEvent_NamedSetOfFiles:
+		g.outputFiles += len(p.NamedSetOfFiles.Files)
+
+	case *bespb.BuildEvent_Completed:
+		g.finish(getTargetLabel(event.Id))
+	}
+}
+
+// earliestActionStart returns the earliest start time among n's own
+// actions, used to anchor the duration of a path hop that has no
+// dependency predecessor (a root target).
+func (n *targetNode) earliestActionStart() (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for _, a := range n.actions {
+		if a.start.IsZero() {
+			continue
+		}
+		if !found || a.start.Before(earliest) {
+			earliest = a.start
+			found = true
+		}
+	}
+	return earliest, found
+}
+
+// finish computes finishTime and criticalPred for label once its Completed
+// event arrives: finishTime is the later of its latest-finishing completed
+// dependency and the latest end among its own actions. criticalPred always
+// records the winning dependency (if any) regardless of whether it was a dependency or the
+// node's own actions that pushed finishTime out further, since the critical
+// path is a chain of targets, not actions; it's only left empty for a root
+// target with no completed dependencies.
+func (g *depGraph) finish(label string) {
+	n := g.node(label)
+
+	var finishTime time.Time
+	var pred string
+	for _, dep := range n.deps {
+		d := g.node(dep)
+		if !d.hasFinish {
+			continue
+		}
+		if pred == "" || d.finishTime.After(finishTime) {
+			finishTime = d.finishTime
+			pred = dep
+		}
+	}
+	for _, a := range n.actions {
+		if a.end.After(finishTime) {
+			finishTime = a.end
+		}
+	}
+
+	n.finishTime = finishTime
+	n.hasFinish = true
+	n.criticalPred = pred
+}
+
+// CriticalHop is one step of the reconstructed critical path: reaching
+// label took Duration longer than whatever finished just before it.
+type CriticalHop struct {
+	Label    string        `json:"label"`
+	Duration time.Duration `json:"durationNanos"`
+}
+
+// hopDuration is how much of the critical path's total time is attributable
+// to n: the gap since its criticalPred finished, or, for a root target with
+// no predecessor, the span of n's own actions.
+func (g *depGraph) hopDuration(n *targetNode) time.Duration {
+	if n.criticalPred != "" {
+		if pred, ok := g.nodes[n.criticalPred]; ok {
+			return n.finishTime.Sub(pred.finishTime)
+		}
+	}
+	if start, ok := n.earliestActionStart(); ok {
+		return n.finishTime.Sub(start)
+	}
+	return 0
+}
+
+// CriticalPath walks criticalPred back from the target with the largest
+// finishTime, returning the chain in chronological (root-first) order.
+func (g *depGraph) CriticalPath() []CriticalHop {
+	var last *targetNode
+	for _, n := range g.nodes {
+		if !n.hasFinish {
+			continue
+		}
+		if last == nil || n.finishTime.After(last.finishTime) {
+			last = n
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	var hops []CriticalHop
+	for n := last; n != nil; {
+		hops = append(hops, CriticalHop{Label: n.label, Duration: g.hopDuration(n)})
+		if n.criticalPred == "" {
+			break
+		}
+		n = g.nodes[n.criticalPred]
+	}
+
+	for i, j := 0, len(hops)-1; i < j; i, j = i+1, j-1 {
+		hops[i], hops[j] = hops[j], hops[i]
+	}
+	return hops
+}
+
+// ActionSummary aggregates every ActionExecuted span sharing a mnemonic,
+// used to report the slowest action kinds across the whole build.
+type ActionSummary struct {
+	Mnemonic      string        `json:"mnemonic"`
+	Count         int           `json:"count"`
+	TotalDuration time.Duration `json:"totalDurationNanos"`
+}
+
+// TopSlowestActions returns up to n mnemonics sorted by total wall time
+// spent executing actions of that kind, slowest first.
+func (g *depGraph) TopSlowestActions(n int) []ActionSummary {
+	totals := make(map[string]*ActionSummary)
+	for _, node := range g.nodes {
+		for _, a := range node.actions {
+			s, ok := totals[a.mnemonic]
+			if !ok {
+				s = &ActionSummary{Mnemonic: a.mnemonic}
+				totals[a.mnemonic] = s
+			}
+			s.Count++
+			s.TotalDuration += a.duration()
+		}
+	}
+
+	summaries := make([]ActionSummary, 0, len(totals))
+	for _, s := range totals {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].TotalDuration > summaries[j].TotalDuration
+	})
+	if n > 0 && len(summaries) > n {
+		summaries = summaries[:n]
+	}
+	return summaries
+}
+
+// AnalysisReport is the result of analyzing a full BEP stream, in the shape
+// printed by -format=text and marshaled by -format=json.
+//
+// There is deliberately no per-configuration breakdown here: this simplified
+// BEP doesn't tag targets or actions with the configuration id they actually
+// ran under, so the only way to bucket wall time by configuration would be
+// to attribute it to whichever Configuration event happened to stream past
+// most recently — which real builds violate constantly (host/exec and
+// target configurations are typically all emitted up front, not interleaved
+// per-action) and would silently misattribute most of the build's time.
+type AnalysisReport struct {
+	CriticalPath []CriticalHop   `json:"criticalPath"`
+	TopActions   []ActionSummary `json:"topActions"`
+	OutputFiles  int             `json:"outputFiles"`
+}
+
+func (g *depGraph) Report(topN int) AnalysisReport {
+	return AnalysisReport{
+		CriticalPath: g.CriticalPath(),
+		TopActions:   g.TopSlowestActions(topN),
+		OutputFiles:  g.outputFiles,
+	}
+}
+
+func writeReportText(w io.Writer, report AnalysisReport) {
+	fmt.Fprintln(w, "=== Critical Path ===")
+	if len(report.CriticalPath) == 0 {
+		fmt.Fprintln(w, "(no completed targets)")
+	}
+	for _, hop := range report.CriticalPath {
+		fmt.Fprintf(w, "  %-40s +%v\n", hop.Label, hop.Duration)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "=== Top Slowest Actions ===")
+	for _, a := range report.TopActions {
+		fmt.Fprintf(w, "  %-20s %5d actions  %v total\n", a.Mnemonic, a.Count, a.TotalDuration)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Output files: %d\n", report.OutputFiles)
+}
+
+func writeReportJSON(w io.Writer, report AnalysisReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// traceEvent is one Chrome Tracing Format event (the "X" complete-event
+// shape), enough to open the output in chrome://tracing or Perfetto.
+type traceEvent struct {
+	Name string `json:"name"`
+	Cat  string `json:"cat"`
+	Ph   string `json:"ph"`
+	Ts   int64  `json:"ts"`
+	Dur  int64  `json:"dur"`
+	Pid  int    `json:"pid"`
+	Tid  int    `json:"tid"`
+}
+
+// writeTraceJSON emits every action span in g as a Chrome trace event,
+// keyed by mnemonic (name) and owning target label (cat).
+func writeTraceJSON(w io.Writer, g *depGraph) error {
+	var events []traceEvent
+	for _, node := range g.nodes {
+		for _, a := range node.actions {
+			if a.start.IsZero() || a.end.IsZero() {
+				continue
+			}
+			events = append(events, traceEvent{
+				Name: a.mnemonic,
+				Cat:  node.label,
+				Ph:   "X",
+				Ts:   a.start.UnixMicro(),
+				Dur:  a.end.UnixMicro() - a.start.UnixMicro(),
+				Pid:  1,
+				Tid:  1,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}{TraceEvents: events})
+}
+
+// runAnalyze implements the "bepstream analyze" subcommand: it reads a BEP
+// file to completion, builds a depGraph from the events, and reports the
+// critical path and slowest actions.
+func runAnalyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text|json|trace (trace emits Chrome Tracing Format JSON)")
+	top := fs.Int("top", 10, "Number of slowest action mnemonics to report")
+	traceOut := fs.String("o", "trace.json", "Output file for -format=trace")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s analyze [-format text|json|trace] [-top N] [-o trace.json] <bep-binary-file>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nOpen the trace format in chrome://tracing or https://ui.perfetto.dev:\n")
+		fmt.Fprintf(os.Stderr, "  %s analyze -format=trace -o trace.json /tmp/bep.bin\n", os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	filename := fs.Arg(0)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	opts := streamOptions{maxMessageSize: defaultMaxMessageSize}
+	reader := newStreamReader(file, filename, opts, 0)
+
+	graph := newDepGraph()
+	for {
+		event, err := reader.readDelimitedMessage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		graph.Update(event)
+
+		if event.LastMessage {
+			break
+		}
+	}
+
+	switch *format {
+	case "text":
+		writeReportText(os.Stdout, graph.Report(*top))
+	case "json":
+		if err := writeReportJSON(os.Stdout, graph.Report(*top)); err != nil {
+			return fmt.Errorf("failed to marshal report as JSON: %w", err)
+		}
+	case "trace":
+		out, err := os.Create(*traceOut)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *traceOut, err)
+		}
+		defer out.Close()
+		if err := writeTraceJSON(out, graph); err != nil {
+			return fmt.Errorf("failed to write trace to %s: %w", *traceOut, err)
+		}
+		fmt.Printf("Wrote Chrome trace to %s\n", *traceOut)
+	default:
+		return fmt.Errorf("unknown -format %q (want text, json, or trace)", *format)
+	}
+
+	return nil
+}
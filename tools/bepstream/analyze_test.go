@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+func configuredEvent(label string, deps ...string) *bespb.BuildEvent {
+	children := make([]*bespb.BuildEventId, 0, len(deps))
+	for _, dep := range deps {
+		children = append(children, &bespb.BuildEventId{
+			Id: &bespb.BuildEventId_TargetConfigured{
+				TargetConfigured: &bespb.BuildEventId_TargetConfiguredId{Label: dep},
+			},
+		})
+	}
+	return &bespb.BuildEvent{
+		Id: &bespb.BuildEventId{
+			Id: &bespb.BuildEventId_TargetConfigured{
+				TargetConfigured: &bespb.BuildEventId_TargetConfiguredId{Label: label},
+			},
+		},
+		Children: children,
+		Payload:  &bespb.BuildEvent_Configured{Configured: &bespb.TargetConfigured{}},
+	}
+}
+
+func actionEvent(label, mnemonic string, start, end time.Time) *bespb.BuildEvent {
+	return &bespb.BuildEvent{
+		Payload: &bespb.BuildEvent_Action{
+			Action: &bespb.ActionExecuted{
+				Label:     label,
+				Type:      mnemonic,
+				Success:   true,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
+		},
+	}
+}
+
+func completedEvent(label string) *bespb.BuildEvent {
+	return &bespb.BuildEvent{
+		Id: &bespb.BuildEventId{
+			Id: &bespb.BuildEventId_TargetCompleted{
+				TargetCompleted: &bespb.BuildEventId_TargetCompletedId{Label: label},
+			},
+		},
+		Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: true}},
+	}
+}
+
+func TestDepGraphCriticalPath(t *testing.T) {
+	base := time.Date(2026, 7, 27, 12, 0, 0, 0, time.UTC)
+
+	g := newDepGraph()
+	g.Update(configuredEvent("//pkg:lib"))
+	g.Update(configuredEvent("//pkg:bin", "//pkg:lib"))
+
+	g.Update(actionEvent("//pkg:lib", "CppCompile", base, base.Add(2*time.Second)))
+	g.Update(completedEvent("//pkg:lib"))
+
+	g.Update(actionEvent("//pkg:bin", "CppLink", base.Add(2*time.Second), base.Add(5*time.Second)))
+	g.Update(completedEvent("//pkg:bin"))
+
+	path := g.CriticalPath()
+	if len(path) != 2 {
+		t.Fatalf("len(path) = %d, want 2: %+v", len(path), path)
+	}
+	if path[0].Label != "//pkg:lib" || path[1].Label != "//pkg:bin" {
+		t.Errorf("path = %+v, want [//pkg:lib //pkg:bin]", path)
+	}
+	if path[0].Duration != 2*time.Second {
+		t.Errorf("path[0].Duration = %v, want 2s", path[0].Duration)
+	}
+	if path[1].Duration != 3*time.Second {
+		t.Errorf("path[1].Duration = %v, want 3s", path[1].Duration)
+	}
+}
+
+func TestDepGraphTopSlowestActions(t *testing.T) {
+	base := time.Date(2026, 7, 27, 12, 0, 0, 0, time.UTC)
+
+	g := newDepGraph()
+	g.Update(actionEvent("//pkg:a", "CppCompile", base, base.Add(3*time.Second)))
+	g.Update(actionEvent("//pkg:b", "CppCompile", base, base.Add(1*time.Second)))
+	g.Update(actionEvent("//pkg:c", "Javac", base, base.Add(5*time.Second)))
+
+	top := g.TopSlowestActions(10)
+	if len(top) != 2 {
+		t.Fatalf("len(top) = %d, want 2: %+v", len(top), top)
+	}
+	if top[0].Mnemonic != "Javac" || top[0].TotalDuration != 5*time.Second {
+		t.Errorf("top[0] = %+v, want Javac/5s", top[0])
+	}
+	if top[1].Mnemonic != "CppCompile" || top[1].Count != 2 || top[1].TotalDuration != 4*time.Second {
+		t.Errorf("top[1] = %+v, want CppCompile/2/4s", top[1])
+	}
+}
+
+func TestWriteReportText(t *testing.T) {
+	g := newDepGraph()
+	var buf bytes.Buffer
+	writeReportText(&buf, g.Report(10))
+
+	out := buf.String()
+	if !bytes.Contains(buf.Bytes(), []byte("=== Critical Path ===")) {
+		t.Errorf("output missing critical path header: %s", out)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("(no completed targets)")) {
+		t.Errorf("expected empty-graph placeholder, got: %s", out)
+	}
+}
@@ -0,0 +1,232 @@
+// Package filter implements a tiny predicate DSL for selecting BuildEvents,
+// e.g. "payload=completed && !success" or `type=~"Cpp.*"`. Expressions
+// are evaluated against a reflected protoreflect.Message, so a single
+// implementation works for every payload oneof case without a hand-coded
+// switch per field.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Expr is a compiled filter expression.
+type Expr interface {
+	Eval(msg protoreflect.Message) bool
+}
+
+// Parse compiles a filter expression into an Expr.
+//
+// Grammar:
+//
+//	expr       := or
+//	or         := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | primary
+//	primary    := "(" or ")" | comparison
+//	comparison := field [ ( "==" | "=" | "=~" ) value ]
+//
+// A bare field with no operator is truthy if it resolves to a non-zero
+// scalar, a non-empty string, or a populated message/oneof case.
+func Parse(expr string) (Expr, error) {
+	p := &parser{lex: newLexer(expr)}
+	p.advance()
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("filter: unexpected token %q", p.tok.text)
+	}
+	return e, nil
+}
+
+// Match parses expr and evaluates it against msg in one step. Callers
+// filtering many messages against the same expression should call Parse
+// once and reuse the Expr instead.
+func Match(expr string, msg proto.Message) (bool, error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return false, err
+	}
+	return e.Eval(msg.ProtoReflect()), nil
+}
+
+type andExpr struct{ left, right Expr }
+
+func (e *andExpr) Eval(msg protoreflect.Message) bool { return e.left.Eval(msg) && e.right.Eval(msg) }
+
+type orExpr struct{ left, right Expr }
+
+func (e *orExpr) Eval(msg protoreflect.Message) bool { return e.left.Eval(msg) || e.right.Eval(msg) }
+
+type notExpr struct{ inner Expr }
+
+func (e *notExpr) Eval(msg protoreflect.Message) bool { return !e.inner.Eval(msg) }
+
+// cmpExpr matches a single "field", "field==value", or "field=~regexp"
+// comparison against a resolved field of the message.
+type cmpExpr struct {
+	field string
+	op    string // "", "==", "=~"
+	value string
+}
+
+func (e *cmpExpr) Eval(msg protoreflect.Message) bool {
+	val, fd, ok := resolveField(msg, e.field)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "":
+		return truthy(val)
+	case "==":
+		return valueString(val, fd) == e.value
+	case "=~":
+		re, err := regexp.Compile(e.value)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(valueString(val, fd))
+	default:
+		return false
+	}
+}
+
+func truthy(v protoreflect.Value) bool {
+	switch x := v.Interface().(type) {
+	case bool:
+		return x
+	case string:
+		return x != ""
+	case protoreflect.EnumNumber:
+		return x != 0
+	case protoreflect.Message:
+		return x.IsValid()
+	default:
+		return fmt.Sprint(x) != "" && fmt.Sprint(x) != "0"
+	}
+}
+
+// valueString renders v as the string a filter expression compares against.
+// fd is the field v came from, or nil if v was synthesized (e.g. the
+// resolved case name of a oneof); it's only consulted to resolve enum
+// fields (TestResult.status, TestSummary.overall_status, ...) to their
+// symbolic name (e.g. "PASSED") instead of the underlying int32, since
+// that's what a filter like `status==PASSED` or `status=~"PASS.*"` expects.
+func valueString(v protoreflect.Value, fd protoreflect.FieldDescriptor) string {
+	if fd != nil && fd.Kind() == protoreflect.EnumKind {
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+	}
+	switch x := v.Interface().(type) {
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case []byte:
+		return string(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// resolveField resolves a dotted field path (e.g. "completed.success" or
+// just "success") against msg. "payload" is a special-cased alias for the
+// oneof field named "payload" and resolves to the name of whichever case is
+// currently set (e.g. "completed"), matching the example `payload=completed`.
+// As a non-final segment it steps into whichever case is set, so
+// "payload.success" reads success from the current payload; a case name
+// after it ("payload.completed") does not resolve.
+//
+// A bare, unqualified name that isn't a direct field of msg falls back to a
+// depth-first search through msg's currently populated submessages, so
+// filters like `label==//foo:bar` or `type=~"Cpp.*"` work without the
+// caller needing to know which payload case or how deep the field lives.
+func resolveField(msg protoreflect.Message, path string) (protoreflect.Value, protoreflect.FieldDescriptor, bool) {
+	segments := strings.Split(path, ".")
+	cur := msg
+
+	for i, seg := range segments {
+		if oneof := findOneof(cur.Descriptor(), seg); oneof != nil {
+			fd := cur.WhichOneof(oneof)
+			if fd == nil {
+				return protoreflect.Value{}, nil, false
+			}
+			if i == len(segments)-1 {
+				return protoreflect.ValueOfString(string(fd.Name())), nil, true
+			}
+			cur = cur.Get(fd).Message()
+			continue
+		}
+
+		fd := findField(cur.Descriptor(), seg)
+		if fd == nil {
+			if i == 0 && len(segments) == 1 {
+				return searchRecursive(cur, seg)
+			}
+			return protoreflect.Value{}, nil, false
+		}
+		if !cur.Has(fd) {
+			return protoreflect.Value{}, nil, false
+		}
+		v := cur.Get(fd)
+		if i == len(segments)-1 {
+			return v, fd, true
+		}
+		if fd.Kind() != protoreflect.MessageKind {
+			return protoreflect.Value{}, nil, false
+		}
+		cur = v.Message()
+	}
+	return protoreflect.Value{}, nil, false
+}
+
+func searchRecursive(msg protoreflect.Message, name string) (protoreflect.Value, protoreflect.FieldDescriptor, bool) {
+	if fd := findField(msg.Descriptor(), name); fd != nil && msg.Has(fd) {
+		return msg.Get(fd), fd, true
+	}
+
+	var found protoreflect.Value
+	var foundFd protoreflect.FieldDescriptor
+	ok := false
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return true
+		}
+		if nested, nestedFd, nestedOK := searchRecursive(v.Message(), name); nestedOK {
+			found, foundFd, ok = nested, nestedFd, true
+			return false
+		}
+		return true
+	})
+	return found, foundFd, ok
+}
+
+func findField(desc protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if strings.EqualFold(string(fd.Name()), name) || strings.EqualFold(fd.JSONName(), name) {
+			return fd
+		}
+	}
+	return nil
+}
+
+func findOneof(desc protoreflect.MessageDescriptor, name string) protoreflect.OneofDescriptor {
+	oneofs := desc.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+		if strings.EqualFold(string(od.Name()), name) {
+			return od
+		}
+	}
+	return nil
+}
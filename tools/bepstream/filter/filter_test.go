@@ -0,0 +1,148 @@
+package filter
+
+import (
+	"testing"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+func TestParseAndEval(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		event *bespb.BuildEvent
+		want  bool
+	}{
+		{
+			name: "payload=completed matches completed events",
+			expr: "payload=completed",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: true}},
+			},
+			want: true,
+		},
+		{
+			name: "payload=completed does not match other payloads",
+			expr: "payload=completed",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}},
+			},
+			want: false,
+		},
+		{
+			name: "payload=completed && !success matches failed targets",
+			expr: "payload=completed && !success",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: false}},
+			},
+			want: true,
+		},
+		{
+			name: "payload=completed && !success excludes successful targets",
+			expr: "payload=completed && !success",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: true}},
+			},
+			want: false,
+		},
+		{
+			name: "type=~ matches a regexp against a nested field",
+			expr: `payload=action && type=~"Cpp.*"`,
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "CppCompile"}},
+			},
+			want: true,
+		},
+		{
+			name: "type=~ rejects a non-matching regexp",
+			expr: `payload=action && type=~"Cpp.*"`,
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "Javac"}},
+			},
+			want: false,
+		},
+		{
+			name: "label== matches a nested label via recursive search",
+			expr: "label==//foo:bar",
+			event: &bespb.BuildEvent{
+				Id: &bespb.BuildEventId{
+					Id: &bespb.BuildEventId_TargetCompleted{
+						TargetCompleted: &bespb.BuildEventId_TargetCompletedId{Label: "//foo:bar"},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "|| matches if either side matches",
+			expr: "payload=completed || payload=action",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{}},
+			},
+			want: true,
+		},
+		{
+			name: "parentheses group precedence",
+			expr: "payload=action && (type=~\"Go.*\" || type=~\"Cpp.*\")",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "GoCompile"}},
+			},
+			want: true,
+		},
+		{
+			name: "enum field compares against its symbolic name",
+			expr: "status==PASSED",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_TestResult{TestResult: &bespb.TestResult{Status: bespb.TestStatus_PASSED}},
+			},
+			want: true,
+		},
+		{
+			name: "enum field does not match the wrong symbolic name",
+			expr: "status==PASSED",
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_TestResult{TestResult: &bespb.TestResult{Status: bespb.TestStatus_FAILED}},
+			},
+			want: false,
+		},
+		{
+			name: "enum field matches a regexp against its symbolic name",
+			expr: `status=~"PASS.*"`,
+			event: &bespb.BuildEvent{
+				Payload: &bespb.BuildEvent_TestResult{TestResult: &bespb.TestResult{Status: bespb.TestStatus_PASSED}},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.expr, err)
+			}
+			got := expr.Eval(tt.event.ProtoReflect())
+			if got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"payload=",
+		"payload==completed &&",
+		"(payload=completed",
+		"payload=completed)",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); err == nil {
+				t.Errorf("Parse(%q) expected an error, got nil", expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // bare field name or unquoted value (letters, digits, ._/:*-)
+	tokString           // quoted value, e.g. "Cpp.*"
+	tokAnd              // &&
+	tokOr               // ||
+	tokNot              // !
+	tokEq               // == or =
+	tokMatch            // =~
+	tokLParen           // (
+	tokRParen           // )
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: input}
+}
+
+func isWordChar(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	case strings.ContainsRune("._/:*-", rune(b)):
+		return true
+	}
+	return false
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && l.input[l.pos] == ' ' {
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF}, nil
+	}
+
+	switch c := l.input[l.pos]; {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "("}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")"}, nil
+	case c == '!':
+		l.pos++
+		return token{kind: tokNot, text: "!"}, nil
+	case c == '&' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '&':
+		l.pos += 2
+		return token{kind: tokAnd, text: "&&"}, nil
+	case c == '|' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '|':
+		l.pos += 2
+		return token{kind: tokOr, text: "||"}, nil
+	case c == '=' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '=':
+		l.pos += 2
+		return token{kind: tokEq, text: "=="}, nil
+	case c == '=' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '~':
+		l.pos += 2
+		return token{kind: tokMatch, text: "=~"}, nil
+	case c == '=':
+		l.pos++
+		return token{kind: tokEq, text: "="}, nil
+	case c == '"':
+		return l.lexString()
+	case isWordChar(c):
+		start := l.pos
+		for l.pos < len(l.input) && isWordChar(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokWord, text: l.input[start:l.pos]}, nil
+	default:
+		return token{}, fmt.Errorf("filter: unexpected character %q at offset %d", c, l.pos)
+	}
+}
+
+func (l *lexer) lexString() (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for l.pos < len(l.input) && l.input[l.pos] != '"' {
+		if l.input[l.pos] == '\\' && l.pos+1 < len(l.input) {
+			l.pos++
+		}
+		sb.WriteByte(l.input[l.pos])
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return token{}, fmt.Errorf("filter: unterminated string starting at offset %d", start)
+	}
+	l.pos++ // closing quote
+	return token{kind: tokString, text: sb.String()}, nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+	err error
+}
+
+func (p *parser) advance() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, p.err
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+	return left, p.err
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.tok.kind == tokNot {
+		p.advance()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.tok.kind == tokLParen {
+		p.advance()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("filter: expected ')', got %q", p.tok.text)
+		}
+		p.advance()
+		return e, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokWord {
+		return nil, fmt.Errorf("filter: expected field name, got %q", p.tok.text)
+	}
+	field := p.tok.text
+	p.advance()
+
+	switch p.tok.kind {
+	case tokEq, tokMatch:
+		op := "=="
+		if p.tok.kind == tokMatch {
+			op = "=~"
+		}
+		p.advance()
+		if p.tok.kind != tokWord && p.tok.kind != tokString {
+			return nil, fmt.Errorf("filter: expected value after %q, got %q", field, p.tok.text)
+		}
+		value := p.tok.text
+		p.advance()
+		return &cmpExpr{field: field, op: op, value: value}, nil
+	default:
+		return &cmpExpr{field: field}, nil
+	}
+}
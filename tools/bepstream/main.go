@@ -5,24 +5,56 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"sync"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
 
+	buildv1 "google.golang.org/genproto/googleapis/devtools/build/v1"
+
+	"github.com/example/bep-demo/tools/bepstream/filter"
+	"github.com/example/bep-demo/tools/bepstream/metrics"
 	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+	"github.com/example/bep-demo/tools/bepstream/server"
 )
 
 var (
-	followMode  = flag.Bool("f", false, "Follow mode: wait for new data as the file is being written")
+	followMode   = flag.Bool("f", false, "Follow mode: wait for new data as the file is being written")
 	pollInterval = flag.Duration("poll", 100*time.Millisecond, "Poll interval when following (default 100ms)")
 	timeout      = flag.Duration("timeout", 5*time.Minute, "Timeout for follow mode (default 5m)")
+	grpcAddr     = flag.String("grpc", "", "Start a Publish Build Event Service gRPC server on this address (e.g. :8080) and print a summary of the stream it receives, instead of reading a file")
+
+	exportListen  = flag.String("listen", "", "Expose buildStats as Prometheus metrics on this address's /metrics endpoint as events stream in (e.g. :9090)")
+	pushGateway   = flag.String("push-gateway", "", "Push metrics to this Prometheus Pushgateway URL on an interval, in addition to or instead of -listen")
+	pushInterval  = flag.Duration("push-interval", 10*time.Second, "Interval between pushes to -push-gateway")
+	pushJob       = flag.String("push-job", "bepstream", "Pushgateway job label to push metrics under")
+	omitProgLabel = flag.Bool("omit-prog-label", false, "Strip the high-cardinality mnemonic label from bep_actions_executed_total, collapsing it to a single series")
+
+	format     = flag.String("format", "text", "Per-event output format: text|json|ndjson (json and ndjson are equivalent: one protojson-encoded message per line)")
+	filterExpr = flag.String("filter", "", `Only emit events matching this expression, e.g. "payload=completed && !success" or 'type=~"Cpp.*"'`)
+
+	resumeMode     = flag.Bool("resume", false, "With -f, resume from the offset checkpointed in <file>.bep-offset instead of reading from the start")
+	maxMessageSize = flag.Int64("max-message-size", defaultMaxMessageSize, "Maximum size in bytes of a single delimited BuildEvent message; larger messages are rejected")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		if err := runAnalyze(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [-f] [-poll duration] [-timeout duration] <bep-binary-file>\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
@@ -31,20 +63,52 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\nFor streaming mode, start this tool first with -f, then run bazel:\n")
 		fmt.Fprintf(os.Stderr, "  Terminal 1: %s -f /tmp/bep.bin\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  Terminal 2: bazel build --build_event_binary_file=/tmp/bep.bin //...\n")
+		fmt.Fprintf(os.Stderr, "\nFor gRPC mode, start this tool with -grpc, then point bazel at it:\n")
+		fmt.Fprintf(os.Stderr, "  Terminal 1: %s -grpc :8080\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Terminal 2: bazel build --bes_backend=grpc://localhost:8080 //...\n")
+		fmt.Fprintf(os.Stderr, "\nTo export live metrics while following a build, add -listen and/or -push-gateway;\n")
+		fmt.Fprintf(os.Stderr, "add -omit-prog-label if the build has too many distinct action mnemonics to scrape:\n")
+		fmt.Fprintf(os.Stderr, "  %s -f -listen :9090 /tmp/bep.bin\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nTo stream events as JSON for piping into jq/Loki, use -format and -filter:\n")
+		fmt.Fprintf(os.Stderr, "  %s -format=ndjson -filter='payload=completed && !success' /tmp/bep.bin\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nTo survive a restart, follow with -resume: it seeks to the offset checkpointed\n")
+		fmt.Fprintf(os.Stderr, "in <file>.bep-offset and reopens the file if it's truncated or rotated:\n")
+		fmt.Fprintf(os.Stderr, "  %s -f -resume /tmp/bep.bin\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nFor critical-path and dependency analysis of a finished build, use the\n")
+		fmt.Fprintf(os.Stderr, "analyze subcommand (run %s analyze -h for its own flags):\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s analyze /tmp/bep.bin\n", os.Args[0])
 	}
 	flag.Parse()
 
+	opts := streamOptions{
+		follow:         *followMode,
+		pollInterval:   *pollInterval,
+		timeout:        *timeout,
+		exportListen:   *exportListen,
+		pushGateway:    *pushGateway,
+		pushInterval:   *pushInterval,
+		pushJob:        *pushJob,
+		omitProgLabel:  *omitProgLabel,
+		format:         *format,
+		filterExpr:     *filterExpr,
+		resume:         *resumeMode,
+		maxMessageSize: *maxMessageSize,
+	}
+
+	if *grpcAddr != "" {
+		if err := runGRPCServer(*grpcAddr, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if flag.NArg() < 1 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	filename := flag.Arg(0)
-	opts := streamOptions{
-		follow:       *followMode,
-		pollInterval: *pollInterval,
-		timeout:      *timeout,
-	}
 	if err := streamBEP(filename, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -55,9 +119,27 @@ type streamOptions struct {
 	follow       bool
 	pollInterval time.Duration
 	timeout      time.Duration
+
+	exportListen  string
+	pushGateway   string
+	pushInterval  time.Duration
+	pushJob       string
+	omitProgLabel bool
+
+	format     string
+	filterExpr string
+
+	resume         bool
+	maxMessageSize int64
 }
 
 func streamBEP(filename string, opts streamOptions) error {
+	switch opts.format {
+	case "", "text", "json", "ndjson":
+	default:
+		return fmt.Errorf("unknown -format %q (want text, json, or ndjson)", opts.format)
+	}
+
 	var file *os.File
 	var err error
 
@@ -71,12 +153,69 @@ func streamBEP(filename string, opts streamOptions) error {
 	}
 	defer file.Close()
 
-	reader := newStreamReader(file, opts)
+	cpPath := checkpointPath(filename)
+	var resumeOffset int64
+	if opts.resume {
+		resumeOffset, err = readCheckpoint(cpPath)
+		if err != nil {
+			return fmt.Errorf("failed to read checkpoint %s: %w", cpPath, err)
+		}
+		if resumeOffset > 0 {
+			if info, statErr := file.Stat(); statErr == nil {
+				if validated := validateResumeOffset(resumeOffset, info.Size()); validated != resumeOffset {
+					fmt.Fprintf(os.Stderr, "warning: checkpoint offset %d in %s exceeds current size of %s (%d bytes); ignoring checkpoint and starting from 0\n", resumeOffset, cpPath, filename, info.Size())
+					resumeOffset = validated
+				}
+			}
+		}
+		if resumeOffset > 0 {
+			if _, err := file.Seek(resumeOffset, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to seek to checkpoint offset %d: %w", resumeOffset, err)
+			}
+			fmt.Printf("Resuming from checkpoint offset %d (%s)\n", resumeOffset, cpPath)
+		}
+	}
+
+	reader := newStreamReader(file, filename, opts, resumeOffset)
 	eventCount := 0
 	stats := &buildStats{}
 
-	fmt.Println("=== BEP Stream Summary ===")
-	fmt.Println()
+	var store *metrics.Store
+	if opts.exportListen != "" || opts.pushGateway != "" {
+		store = metrics.NewStore(opts.omitProgLabel)
+	}
+	if opts.exportListen != "" {
+		go func() {
+			if err := store.Serve(opts.exportListen); err != nil {
+				fmt.Fprintf(os.Stderr, "metrics server on %s stopped: %v\n", opts.exportListen, err)
+			}
+		}()
+		fmt.Printf("Exposing metrics on http://%s/metrics\n", opts.exportListen)
+	}
+	if opts.pushGateway != "" {
+		pushCtx, cancelPush := context.WithCancel(context.Background())
+		defer cancelPush()
+		go func() {
+			if err := store.Push(pushCtx, opts.pushGateway, opts.pushInterval, opts.pushJob); err != nil && err != context.Canceled {
+				fmt.Fprintf(os.Stderr, "metrics push to %s stopped: %v\n", opts.pushGateway, err)
+			}
+		}()
+		fmt.Printf("Pushing metrics to %s every %v\n", opts.pushGateway, opts.pushInterval)
+	}
+
+	var matchExpr filter.Expr
+	if opts.filterExpr != "" {
+		matchExpr, err = filter.Parse(opts.filterExpr)
+		if err != nil {
+			return fmt.Errorf("invalid -filter: %w", err)
+		}
+	}
+
+	textMode := opts.format == "" || opts.format == "text"
+	if textMode {
+		fmt.Println("=== BEP Stream Summary ===")
+		fmt.Println()
+	}
 
 	startTime := time.Now()
 	for {
@@ -93,22 +232,150 @@ func streamBEP(filename string, opts streamOptions) error {
 		}
 
 		eventCount++
-		processEvent(event, stats)
+		updateStats(event, stats)
+		stats.bytesRead = reader.Offset()
+		if store != nil {
+			store.Update(event)
+		}
+		if opts.follow {
+			if err := writeCheckpoint(cpPath, reader.Offset()); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to write checkpoint %s: %v\n", cpPath, err)
+			}
+		}
+
+		if matchExpr == nil || matchExpr.Eval(event.ProtoReflect()) {
+			if textMode {
+				emitEventText(event)
+			} else {
+				line, err := protojson.MarshalOptions{}.Marshal(event)
+				if err != nil {
+					return fmt.Errorf("failed to marshal event as JSON: %w", err)
+				}
+				fmt.Println(string(line))
+			}
+		}
 
 		if event.LastMessage {
-			fmt.Println("[Last message received]")
+			if textMode {
+				fmt.Println("[Last message received]")
+			}
 			break
 		}
 	}
 
-	fmt.Println()
-	fmt.Println("=== Build Statistics ===")
-	fmt.Printf("Total events: %d\n", eventCount)
-	stats.printSummary()
+	if store != nil && stats.buildStarted && stats.buildFinished {
+		store.RecordBuildDuration(stats.endTime.Sub(stats.startTime))
+	}
+
+	if textMode {
+		fmt.Println()
+		fmt.Println("=== Build Statistics ===")
+		fmt.Printf("Total events: %d\n", eventCount)
+		stats.printSummary()
+	}
 
 	return nil
 }
 
+// runGRPCServer starts a PublishBuildEventService gRPC server on addr and
+// blocks, printing a running summary of the events it receives the same way
+// streamBEP does for the file reader. It only returns once the server
+// stops, which currently only happens on a listen or serve error.
+//
+// Every PublishBuildToolEventStream RPC is its own build (Bazel opens one
+// stream per invocation), so each stream gets a freshly allocated
+// buildStats/eventCount/mutex instead of sharing one across the server's
+// lifetime — otherwise concurrent builds would corrupt each other's counts,
+// and sequential builds would never reset.
+//
+// -listen/-push-gateway/-omit-prog-label are wired in the same way as
+// streamBEP, since a long-lived gRPC sink is exactly the "scraped live"
+// scenario they're for; unlike buildStats, the metrics.Store is shared
+// across streams so its counters keep accumulating build over build.
+// -format/-filter/-resume/-max-message-size only make sense for the file
+// reader (there's no file here to format/filter/checkpoint/bound), so
+// runGRPCServer rejects them up front instead of silently ignoring them.
+func runGRPCServer(addr string, opts streamOptions) error {
+	if opts.format != "" && opts.format != "text" {
+		return fmt.Errorf("-format has no effect with -grpc; it only applies to the file reader")
+	}
+	if opts.filterExpr != "" {
+		return fmt.Errorf("-filter has no effect with -grpc; it only applies to the file reader")
+	}
+	if opts.resume {
+		return fmt.Errorf("-resume has no effect with -grpc; there's no file to checkpoint against")
+	}
+	if opts.maxMessageSize != 0 && opts.maxMessageSize != defaultMaxMessageSize {
+		return fmt.Errorf("-max-message-size has no effect with -grpc; it only bounds the file reader")
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	fmt.Println("=== BEP gRPC Server ===")
+	fmt.Printf("Listening for PublishBuildEventService traffic on %s\n", addr)
+	fmt.Println()
+
+	var store *metrics.Store
+	if opts.exportListen != "" || opts.pushGateway != "" {
+		store = metrics.NewStore(opts.omitProgLabel)
+	}
+	if opts.exportListen != "" {
+		go func() {
+			if err := store.Serve(opts.exportListen); err != nil {
+				fmt.Fprintf(os.Stderr, "metrics server on %s stopped: %v\n", opts.exportListen, err)
+			}
+		}()
+		fmt.Printf("Exposing metrics on http://%s/metrics\n", opts.exportListen)
+	}
+	if opts.pushGateway != "" {
+		pushCtx, cancelPush := context.WithCancel(context.Background())
+		defer cancelPush()
+		go func() {
+			if err := store.Push(pushCtx, opts.pushGateway, opts.pushInterval, opts.pushJob); err != nil && err != context.Canceled {
+				fmt.Fprintf(os.Stderr, "metrics push to %s stopped: %v\n", opts.pushGateway, err)
+			}
+		}()
+		fmt.Printf("Pushing metrics to %s every %v\n", opts.pushGateway, opts.pushInterval)
+	}
+
+	grpcServer := grpc.NewServer()
+	buildv1.RegisterPublishBuildEventServer(grpcServer, server.New(func() server.EventHandler {
+		var (
+			mu         sync.Mutex
+			eventCount int
+			stats      = &buildStats{}
+		)
+
+		return func(event *bespb.BuildEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			eventCount++
+			processEvent(event, stats)
+			if store != nil {
+				store.Update(event)
+			}
+
+			if event.LastMessage {
+				if store != nil && stats.buildStarted && stats.buildFinished {
+					store.RecordBuildDuration(stats.endTime.Sub(stats.startTime))
+				}
+				fmt.Println("[Last message received]")
+				fmt.Println()
+				fmt.Println("=== Build Statistics ===")
+				fmt.Printf("Total events: %d\n", eventCount)
+				stats.printSummary()
+				fmt.Println()
+			}
+		}
+	}))
+
+	return grpcServer.Serve(lis)
+}
+
 func waitForFile(filename string, timeout time.Duration) (*os.File, error) {
 	deadline := time.Now().Add(timeout)
 	pollInterval := 100 * time.Millisecond
@@ -129,36 +396,81 @@ func waitForFile(filename string, timeout time.Duration) (*os.File, error) {
 	}
 }
 
+// defaultMaxMessageSize bounds how large a single delimited BuildEvent is
+// allowed to be, and sizes the bufio.Reader so a full message can always be
+// peeked before protodelim.UnmarshalFrom is asked to decode it.
+const defaultMaxMessageSize = 16 << 20 // 16 MiB
+
+// streamReader decodes length-delimited BuildEvents from a file, tolerating
+// a partial varint or partial body at EOF (retrying in follow mode instead
+// of erroring), and transparently reopening the file if it's truncated or
+// rotated out from under it.
 type streamReader struct {
+	filename     string
 	file         *os.File
 	reader       *bufio.Reader
 	follow       bool
 	pollInterval time.Duration
 	timeout      time.Duration
+
+	maxMessageSize int64
+	offset         int64
+
+	lastSize   int64
+	fileID     uint64
+	haveFileID bool
 }
 
-func newStreamReader(file *os.File, opts streamOptions) *streamReader {
-	return &streamReader{
-		file:         file,
-		reader:       bufio.NewReader(file),
-		follow:       opts.follow,
-		pollInterval: opts.pollInterval,
-		timeout:      opts.timeout,
+// newStreamReader wraps file for decoding, starting at startOffset bytes
+// into the stream (file must already be seeked there). filename is used to
+// detect truncation/rotation and may be "" if the caller has no path to
+// re-stat (e.g. an anonymous or in-memory file in tests).
+func newStreamReader(file *os.File, filename string, opts streamOptions, startOffset int64) *streamReader {
+	maxMessageSize := opts.maxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = defaultMaxMessageSize
+	}
+
+	r := &streamReader{
+		filename:       filename,
+		file:           file,
+		follow:         opts.follow,
+		pollInterval:   opts.pollInterval,
+		timeout:        opts.timeout,
+		maxMessageSize: maxMessageSize,
+		offset:         startOffset,
+	}
+	r.reader = bufio.NewReaderSize(file, int(maxMessageSize)+binary.MaxVarintLen64)
+
+	if info, err := file.Stat(); err == nil {
+		r.lastSize = info.Size()
+		r.fileID, r.haveFileID = fileIdentity(info)
 	}
+	return r
+}
+
+// Offset returns the number of bytes of the current file successfully
+// decoded so far, for checkpointing and debuggability.
+func (r *streamReader) Offset() int64 {
+	return r.offset
 }
 
 func (r *streamReader) readDelimitedMessage() (*bespb.BuildEvent, error) {
-	event := &bespb.BuildEvent{}
 	startTime := time.Now()
 
 	for {
-		err := protodelim.UnmarshalFrom(r.reader, event)
-		if err == nil {
-			return event, nil
+		if err := r.checkRotation(); err != nil {
+			return nil, err
 		}
-		if err != io.EOF {
-			return nil, fmt.Errorf("failed to read message: %w", err)
+
+		event, ready, err := r.tryReadMessage()
+		if err != nil {
+			return nil, err
 		}
+		if ready {
+			return event, nil
+		}
+
 		if !r.follow {
 			return nil, io.EOF
 		}
@@ -169,6 +481,90 @@ func (r *streamReader) readDelimitedMessage() (*bespb.BuildEvent, error) {
 	}
 }
 
+// tryReadMessage peeks the length-delimited header and, only once the full
+// message body is confirmed buffered, consumes it with
+// protodelim.UnmarshalFrom. It never lets UnmarshalFrom observe a partial
+// varint or partial body, so a message straddling the current end of the
+// file is left untouched in the reader for the next attempt.
+func (r *streamReader) tryReadMessage() (event *bespb.BuildEvent, ready bool, err error) {
+	header, peekErr := r.reader.Peek(binary.MaxVarintLen64)
+	if len(header) == 0 {
+		if peekErr != nil && peekErr != io.EOF {
+			return nil, false, peekErr
+		}
+		return nil, false, nil
+	}
+
+	msgLen, headerLen := binary.Uvarint(header)
+	if headerLen == 0 {
+		// The varint continues past what's currently available; wait for more.
+		return nil, false, nil
+	}
+	if headerLen < 0 {
+		return nil, false, fmt.Errorf("corrupt message length varint at offset %d", r.offset)
+	}
+	if int64(msgLen) > r.maxMessageSize {
+		return nil, false, fmt.Errorf("message of %d bytes at offset %d exceeds -max-message-size (%d bytes)", msgLen, r.offset, r.maxMessageSize)
+	}
+
+	total := headerLen + int(msgLen)
+	body, peekErr := r.reader.Peek(total)
+	if len(body) < total {
+		if peekErr != nil && peekErr != io.EOF {
+			return nil, false, peekErr
+		}
+		return nil, false, nil
+	}
+
+	event = &bespb.BuildEvent{}
+	if err := protodelim.UnmarshalFrom(r.reader, event); err != nil {
+		return nil, false, fmt.Errorf("failed to decode message at offset %d: %w", r.offset, err)
+	}
+	r.offset += int64(total)
+	return event, true, nil
+}
+
+// checkRotation re-stats the file by path and transparently reopens it if
+// it shrank (truncation) or its inode changed (rotation), the way `tail -F`
+// does. It's a no-op when the reader has no filename to re-stat.
+func (r *streamReader) checkRotation() error {
+	if r.filename == "" {
+		return nil
+	}
+
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		// The file may be briefly absent across a rotation; let the normal
+		// follow/timeout handling in readDelimitedMessage retry.
+		return nil
+	}
+
+	id, haveID := fileIdentity(info)
+	rotated := info.Size() < r.lastSize || (haveID && r.haveFileID && id != r.fileID)
+	r.lastSize = info.Size()
+	if haveID {
+		r.fileID, r.haveFileID = id, true
+	}
+	if !rotated {
+		return nil
+	}
+
+	newFile, err := os.Open(r.filename)
+	if err != nil {
+		return fmt.Errorf("failed to reopen rotated file %s: %w", r.filename, err)
+	}
+	r.file.Close()
+	r.file = newFile
+	r.reader = bufio.NewReaderSize(newFile, int(r.maxMessageSize)+binary.MaxVarintLen64)
+	r.offset = 0
+	r.lastSize = 0
+	if info, err := newFile.Stat(); err == nil {
+		r.lastSize = info.Size()
+		r.fileID, r.haveFileID = fileIdentity(info)
+	}
+	return nil
+}
+
 type buildStats struct {
 	buildStarted    bool
 	buildFinished   bool
@@ -184,6 +580,7 @@ type buildStats struct {
 	testsFailed     int
 	actionsExecuted int
 	progressEvents  int
+	bytesRead       int64
 }
 
 func (s *buildStats) printSummary() {
@@ -204,9 +601,22 @@ func (s *buildStats) printSummary() {
 		fmt.Printf("Tests: %d passed, %d failed (total: %d)\n", s.testsPassed, s.testsFailed, s.testsRun)
 	}
 	fmt.Printf("Progress events: %d\n", s.progressEvents)
+	if s.bytesRead > 0 {
+		fmt.Printf("Bytes read: %d\n", s.bytesRead)
+	}
 }
 
+// processEvent folds event into stats and prints its emoji text summary.
+// It is the combined behavior used by modes that don't support -format/
+// -filter (the gRPC server, for instance); streamBEP itself calls
+// updateStats and emitEventText separately so it can gate printing on a
+// filter and format without skipping stats collection.
 func processEvent(event *bespb.BuildEvent, stats *buildStats) {
+	updateStats(event, stats)
+	emitEventText(event)
+}
+
+func updateStats(event *bespb.BuildEvent, stats *buildStats) {
 	switch p := event.Payload.(type) {
 	case *bespb.BuildEvent_Started:
 		stats.buildStarted = true
@@ -215,7 +625,6 @@ func processEvent(event *bespb.BuildEvent, stats *buildStats) {
 		if p.Started.StartTime != nil {
 			stats.startTime = p.Started.StartTime.AsTime()
 		}
-		fmt.Printf("â–¶ Build started: %s (UUID: %s)\n", p.Started.Command, p.Started.Uuid)
 
 	case *bespb.BuildEvent_Finished:
 		stats.buildFinished = true
@@ -223,60 +632,79 @@ func processEvent(event *bespb.BuildEvent, stats *buildStats) {
 		if p.Finished.FinishTime != nil {
 			stats.endTime = p.Finished.FinishTime.AsTime()
 		}
-		fmt.Printf("â–  Build finished: exit code %d\n", p.Finished.ExitCode.Code)
 
 	case *bespb.BuildEvent_Progress:
 		stats.progressEvents++
 
+	case *bespb.BuildEvent_Completed:
+		stats.targetsBuilt++
+		if !p.Completed.Success {
+			stats.targetsFailed++
+		}
+
+	case *bespb.BuildEvent_Action:
+		stats.actionsExecuted++
+
+	case *bespb.BuildEvent_TestSummary:
+		stats.testsRun++
+		if p.TestSummary.OverallStatus == bespb.TestStatus_PASSED {
+			stats.testsPassed++
+		} else {
+			stats.testsFailed++
+		}
+	}
+}
+
+func emitEventText(event *bespb.BuildEvent) {
+	switch p := event.Payload.(type) {
+	case *bespb.BuildEvent_Started:
+		fmt.Printf("▶ Build started: %s (UUID: %s)\n", p.Started.Command, p.Started.Uuid)
+
+	case *bespb.BuildEvent_Finished:
+		fmt.Printf("■ Build finished: exit code %d\n", p.Finished.ExitCode.Code)
+
 	case *bespb.BuildEvent_Configured:
-		fmt.Printf("  â—‡ Target configured: %s\n", getTargetLabel(event.Id))
+		fmt.Printf("  ◇ Target configured: %s\n", getTargetLabel(event.Id))
 
 	case *bespb.BuildEvent_Completed:
-		stats.targetsBuilt++
-		success := p.Completed.Success
 		label := getTargetLabel(event.Id)
-		if success {
-			fmt.Printf("  âœ“ Target completed: %s\n", label)
+		if p.Completed.Success {
+			fmt.Printf("  ✓ Target completed: %s\n", label)
 		} else {
-			stats.targetsFailed++
-			fmt.Printf("  âœ— Target failed: %s\n", label)
+			fmt.Printf("  ✗ Target failed: %s\n", label)
 		}
 
 	case *bespb.BuildEvent_Action:
-		stats.actionsExecuted++
 		if !p.Action.Success {
-			fmt.Printf("  âœ— Action failed: %s (%s)\n", p.Action.Label, p.Action.Type)
+			fmt.Printf("  ✗ Action failed: %s (%s)\n", p.Action.Label, p.Action.Type)
 		}
 
 	case *bespb.BuildEvent_TestResult:
 		testLabel := getTargetLabel(event.Id)
 		status := p.TestResult.Status
-		fmt.Printf("  âš¡ Test result: %s - %s\n", testLabel, status.String())
+		fmt.Printf("  ⚡ Test result: %s - %s\n", testLabel, status.String())
 
 	case *bespb.BuildEvent_TestSummary:
-		stats.testsRun++
 		testLabel := getTargetLabel(event.Id)
 		status := p.TestSummary.OverallStatus
 		if status == bespb.TestStatus_PASSED {
-			stats.testsPassed++
-			fmt.Printf("  âœ“ Test passed: %s\n", testLabel)
+			fmt.Printf("  ✓ Test passed: %s\n", testLabel)
 		} else {
-			stats.testsFailed++
-			fmt.Printf("  âœ— Test failed: %s (%s)\n", testLabel, status.String())
+			fmt.Printf("  ✗ Test failed: %s (%s)\n", testLabel, status.String())
 		}
 
 	case *bespb.BuildEvent_Aborted:
-		fmt.Printf("  âš  Aborted: %s - %s\n", p.Aborted.Reason.String(), p.Aborted.Description)
+		fmt.Printf("  ⚠ Aborted: %s - %s\n", p.Aborted.Reason.String(), p.Aborted.Description)
 
 	case *bespb.BuildEvent_Configuration:
-		fmt.Printf("  âš™ Configuration: %s (cpu: %s)\n", p.Configuration.Mnemonic, p.Configuration.Cpu)
+		fmt.Printf("  ⚙ Configuration: %s (cpu: %s)\n", p.Configuration.Mnemonic, p.Configuration.Cpu)
 
 	case *bespb.BuildEvent_BuildToolLogs:
-		fmt.Println("  ðŸ“‹ Build tool logs available")
+		fmt.Println("  📋 Build tool logs available")
 
 	case *bespb.BuildEvent_BuildMetrics:
 		if p.BuildMetrics.ActionSummary != nil {
-			fmt.Printf("  ðŸ“Š Metrics: %d actions\n", p.BuildMetrics.ActionSummary.ActionsExecuted)
+			fmt.Printf("  📊 Metrics: %d actions\n", p.BuildMetrics.ActionSummary.ActionsExecuted)
 		}
 	}
 }
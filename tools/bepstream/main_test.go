@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -417,11 +418,11 @@ func TestStreamReader(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		reader := newStreamReader(tmpFile, streamOptions{
+		reader := newStreamReader(tmpFile, tmpFile.Name(), streamOptions{
 			follow:       false,
 			pollInterval: 10 * time.Millisecond,
 			timeout:      100 * time.Millisecond,
-		})
+		}, 0)
 
 		got, err := reader.readDelimitedMessage()
 		if err != nil {
@@ -452,11 +453,11 @@ func TestStreamReader(t *testing.T) {
 		}
 		defer readFile.Close()
 
-		reader := newStreamReader(readFile, streamOptions{
+		reader := newStreamReader(readFile, tmpName, streamOptions{
 			follow:       true,
 			pollInterval: 10 * time.Millisecond,
 			timeout:      1 * time.Second,
-		})
+		}, 0)
 
 		event := &bespb.BuildEvent{
 			LastMessage: true,
@@ -491,6 +492,251 @@ func TestStreamReader(t *testing.T) {
 			t.Fatal("timeout waiting for message")
 		}
 	})
+
+	t.Run("partial body at EOF is left for the next attempt", func(t *testing.T) {
+		event := &bespb.BuildEvent{LastMessage: true}
+		data := encodeDelimitedMessage(t, event)
+
+		tmpFile, err := os.CreateTemp("", "bep-partial-*.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write(data[:len(data)-1]); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpFile.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		reader := newStreamReader(tmpFile, tmpFile.Name(), streamOptions{
+			follow:       false,
+			pollInterval: 10 * time.Millisecond,
+			timeout:      50 * time.Millisecond,
+		}, 0)
+
+		if _, err := reader.readDelimitedMessage(); err != io.EOF {
+			t.Fatalf("expected io.EOF for a partial message, got %v", err)
+		}
+		if reader.Offset() != 0 {
+			t.Errorf("Offset() = %d, want 0 (partial message must not be consumed)", reader.Offset())
+		}
+	})
+
+	t.Run("rejects a message over -max-message-size", func(t *testing.T) {
+		event := &bespb.BuildEvent{LastMessage: true}
+		data := encodeDelimitedMessage(t, event)
+
+		tmpFile, err := os.CreateTemp("", "bep-toobig-*.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpFile.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		reader := newStreamReader(tmpFile, tmpFile.Name(), streamOptions{
+			maxMessageSize: 1,
+		}, 0)
+
+		if _, err := reader.readDelimitedMessage(); err == nil {
+			t.Error("expected an error for a message over -max-message-size")
+		}
+	})
+
+	t.Run("resumes from a non-zero start offset", func(t *testing.T) {
+		events := []*bespb.BuildEvent{
+			{Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}},
+			{LastMessage: true},
+		}
+		var all []byte
+		offsets := make([]int, len(events))
+		for i, e := range events {
+			offsets[i] = len(all)
+			all = append(all, encodeDelimitedMessage(t, e)...)
+		}
+
+		tmpFile, err := os.CreateTemp("", "bep-resume-*.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write(all); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpFile.Seek(int64(offsets[1]), 0); err != nil {
+			t.Fatal(err)
+		}
+
+		reader := newStreamReader(tmpFile, tmpFile.Name(), streamOptions{}, int64(offsets[1]))
+
+		got, err := reader.readDelimitedMessage()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.LastMessage {
+			t.Error("expected to resume directly at the last message")
+		}
+	})
+
+	t.Run("detects truncation and reopens from the start", func(t *testing.T) {
+		full := encodeDelimitedMessage(t, &bespb.BuildEvent{
+			Payload: &bespb.BuildEvent_Started{
+				Started: &bespb.BuildStarted{Command: "a deliberately long command line so this message outsizes the replacement"},
+			},
+		})
+
+		tmpFile, err := os.CreateTemp("", "bep-rotate-*.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write(full); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpFile.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		reader := newStreamReader(tmpFile, tmpFile.Name(), streamOptions{
+			follow:       true,
+			pollInterval: 10 * time.Millisecond,
+			timeout:      200 * time.Millisecond,
+		}, 0)
+
+		if _, err := reader.readDelimitedMessage(); err != nil {
+			t.Fatalf("unexpected error reading first message: %v", err)
+		}
+
+		replacement := encodeDelimitedMessage(t, &bespb.BuildEvent{
+			Payload:     &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}},
+			LastMessage: true,
+		})
+		if err := os.WriteFile(tmpFile.Name(), replacement, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := reader.readDelimitedMessage()
+		if err != nil {
+			t.Fatalf("unexpected error after rotation: %v", err)
+		}
+		if !got.LastMessage {
+			t.Error("expected to re-read the rotated file's message")
+		}
+		if _, isProgress := got.Payload.(*bespb.BuildEvent_Progress); !isProgress {
+			t.Error("expected the rotated file's content, not a leftover from the old file")
+		}
+	})
+}
+
+// captureStdout redirects os.Stdout to a pipe for the duration of fn and
+// returns everything written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe writer: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read pipe: %v", err)
+	}
+	return string(out)
+}
+
+func TestStreamBEPFormatAndFilter(t *testing.T) {
+	t.Run("ndjson format with a filter only emits matching events", func(t *testing.T) {
+		events := []*bespb.BuildEvent{
+			{Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: true}}},
+			{Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: false}}},
+			{LastMessage: true, Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}},
+		}
+
+		var data []byte
+		for _, event := range events {
+			data = append(data, encodeDelimitedMessage(t, event)...)
+		}
+
+		tmpFile, err := os.CreateTemp("", "bep-format-*.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		out := captureStdout(t, func() {
+			err := streamBEP(tmpFile.Name(), streamOptions{
+				format:     "ndjson",
+				filterExpr: "payload=completed && !success",
+			})
+			if err != nil {
+				t.Fatalf("streamBEP returned error: %v", err)
+			}
+		})
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("got %d lines of output, want 1: %q", len(lines), out)
+		}
+		// protojson omits proto3 fields at their default value, so the failed
+		// target carries no "success" key while the successful one would.
+		if !strings.Contains(lines[0], `"completed"`) || strings.Contains(lines[0], `"success"`) {
+			t.Errorf("unexpected line: %s", lines[0])
+		}
+		if strings.Contains(out, "===") {
+			t.Errorf("expected no emoji-text summary in ndjson mode, got: %s", out)
+		}
+	})
+
+	t.Run("unknown format is rejected", func(t *testing.T) {
+		err := streamBEP("does-not-matter.bin", streamOptions{format: "yaml"})
+		if err == nil {
+			t.Fatal("expected an error for an unknown -format")
+		}
+	})
+}
+
+func TestRunGRPCServerRejectsFileOnlyFlags(t *testing.T) {
+	cases := []struct {
+		name string
+		opts streamOptions
+	}{
+		{"format", streamOptions{format: "ndjson", maxMessageSize: defaultMaxMessageSize}},
+		{"filter", streamOptions{filterExpr: "payload=completed", maxMessageSize: defaultMaxMessageSize}},
+		{"resume", streamOptions{resume: true, maxMessageSize: defaultMaxMessageSize}},
+		{"max-message-size", streamOptions{maxMessageSize: defaultMaxMessageSize * 2}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := runGRPCServer("127.0.0.1:0", tc.opts)
+			if err == nil {
+				t.Fatalf("expected an error for -%s combined with -grpc", tc.name)
+			}
+		})
+	}
 }
 
 func encodeDelimitedMessage(t *testing.T, event *bespb.BuildEvent) []byte {
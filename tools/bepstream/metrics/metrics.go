@@ -0,0 +1,131 @@
+// Package metrics exposes a running bepstream's buildStats as Prometheus
+// collectors, so a follow-mode build can be scraped (or pushed to a
+// Pushgateway) live instead of only summarized once it finishes.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+// Store holds the Prometheus collectors bepstream updates as BuildEvents
+// stream in. It registers against its own registry rather than the global
+// default one, so tests (and multiple concurrent builds) never collide.
+type Store struct {
+	registry *prometheus.Registry
+
+	omitMnemonicLabel bool
+
+	targetsBuilt    *prometheus.CounterVec
+	testsTotal      *prometheus.CounterVec
+	actionsExecuted *prometheus.CounterVec
+	buildDuration   prometheus.Histogram
+	progressEvents  prometheus.Counter
+}
+
+// NewStore creates a Store with its collectors registered and ready to
+// update. When omitMnemonicLabel is true, bep_actions_executed_total drops
+// its mnemonic label (collapsing to a single series) so a build with many
+// distinct action mnemonics doesn't blow up cardinality on the scraping
+// side, mirroring the omit-label knob other log-driven exporters expose.
+func NewStore(omitMnemonicLabel bool) *Store {
+	s := &Store{
+		registry:          prometheus.NewRegistry(),
+		omitMnemonicLabel: omitMnemonicLabel,
+		targetsBuilt: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bep_targets_built_total",
+			Help: "Targets completed, labeled by status (ok|failed).",
+		}, []string{"status"}),
+		testsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bep_tests_total",
+			Help: "Test summaries received, labeled by status (passed|failed).",
+		}, []string{"status"}),
+		actionsExecuted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bep_actions_executed_total",
+			Help: "Actions executed, labeled by mnemonic.",
+		}, []string{"mnemonic"}),
+		buildDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "bep_build_duration_seconds",
+			Help:    "Wall-clock duration of completed builds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		progressEvents: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bep_progress_events_total",
+			Help: "Progress events received.",
+		}),
+	}
+	s.registry.MustRegister(s.targetsBuilt, s.testsTotal, s.actionsExecuted, s.buildDuration, s.progressEvents)
+	return s
+}
+
+// Update folds a single decoded BuildEvent into the store's collectors. It
+// is meant to be called alongside processEvent as events stream in.
+func (s *Store) Update(event *bespb.BuildEvent) {
+	switch p := event.Payload.(type) {
+	case *bespb.BuildEvent_Progress:
+		s.progressEvents.Inc()
+
+	case *bespb.BuildEvent_Completed:
+		if p.Completed.Success {
+			s.targetsBuilt.WithLabelValues("ok").Inc()
+		} else {
+			s.targetsBuilt.WithLabelValues("failed").Inc()
+		}
+
+	case *bespb.BuildEvent_Action:
+		mnemonic := p.Action.Type
+		if s.omitMnemonicLabel {
+			mnemonic = ""
+		}
+		s.actionsExecuted.WithLabelValues(mnemonic).Inc()
+
+	case *bespb.BuildEvent_TestSummary:
+		if p.TestSummary.OverallStatus == bespb.TestStatus_PASSED {
+			s.testsTotal.WithLabelValues("passed").Inc()
+		} else {
+			s.testsTotal.WithLabelValues("failed").Inc()
+		}
+	}
+}
+
+// RecordBuildDuration observes a completed build's wall-clock duration.
+func (s *Store) RecordBuildDuration(d time.Duration) {
+	s.buildDuration.Observe(d.Seconds())
+}
+
+// Serve exposes the store's collectors on addr at /metrics and blocks until
+// the HTTP server stops.
+func (s *Store) Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
+	return http.ListenAndServe(addr, mux)
+}
+
+// Push gathers the store's collectors and pushes them to a Prometheus
+// Pushgateway at gatewayURL every interval, until ctx is canceled or a push
+// fails; a failed push is returned as an error and is not retried.
+func (s *Store) Push(ctx context.Context, gatewayURL string, interval time.Duration, jobName string) error {
+	pusher := push.New(gatewayURL, jobName).Gatherer(s.registry)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := pusher.Push(); err != nil {
+				return fmt.Errorf("failed to push metrics to %s: %w", gatewayURL, err)
+			}
+		}
+	}
+}
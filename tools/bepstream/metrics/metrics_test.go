@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+func TestStoreUpdate(t *testing.T) {
+	t.Run("Completed event increments targets by status", func(t *testing.T) {
+		s := NewStore(false)
+
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: true}}})
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Completed{Completed: &bespb.TargetComplete{Success: false}}})
+
+		if got := testutil.ToFloat64(s.targetsBuilt.WithLabelValues("ok")); got != 1 {
+			t.Errorf("targets_built_total{status=ok} = %v, want 1", got)
+		}
+		if got := testutil.ToFloat64(s.targetsBuilt.WithLabelValues("failed")); got != 1 {
+			t.Errorf("targets_built_total{status=failed} = %v, want 1", got)
+		}
+	})
+
+	t.Run("Action event increments actions by mnemonic", func(t *testing.T) {
+		s := NewStore(false)
+
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "Javac"}}})
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "Javac"}}})
+
+		if got := testutil.ToFloat64(s.actionsExecuted.WithLabelValues("Javac")); got != 2 {
+			t.Errorf("actions_executed_total{mnemonic=Javac} = %v, want 2", got)
+		}
+	})
+
+	t.Run("omitMnemonicLabel collapses actions to a single series", func(t *testing.T) {
+		s := NewStore(true)
+
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "Javac"}}})
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Action{Action: &bespb.ActionExecuted{Type: "CppCompile"}}})
+
+		if got := testutil.ToFloat64(s.actionsExecuted.WithLabelValues("")); got != 2 {
+			t.Errorf("actions_executed_total{mnemonic=\"\"} = %v, want 2", got)
+		}
+	})
+
+	t.Run("TestSummary event increments tests by status", func(t *testing.T) {
+		s := NewStore(false)
+
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_TestSummary{TestSummary: &bespb.TestSummary{OverallStatus: bespb.TestStatus_PASSED}}})
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_TestSummary{TestSummary: &bespb.TestSummary{OverallStatus: bespb.TestStatus_FAILED}}})
+
+		if got := testutil.ToFloat64(s.testsTotal.WithLabelValues("passed")); got != 1 {
+			t.Errorf("tests_total{status=passed} = %v, want 1", got)
+		}
+		if got := testutil.ToFloat64(s.testsTotal.WithLabelValues("failed")); got != 1 {
+			t.Errorf("tests_total{status=failed} = %v, want 1", got)
+		}
+	})
+
+	t.Run("Progress event increments counter", func(t *testing.T) {
+		s := NewStore(false)
+
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}})
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}})
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}})
+
+		if got := testutil.ToFloat64(s.progressEvents); got != 3 {
+			t.Errorf("progress_events_total = %v, want 3", got)
+		}
+	})
+}
+
+func TestStoreGather(t *testing.T) {
+	t.Run("exposes collectors in Prometheus text format", func(t *testing.T) {
+		s := NewStore(false)
+		s.Update(&bespb.BuildEvent{Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}})
+
+		if err := testutil.GatherAndCompare(s.registry, strings.NewReader(`
+# HELP bep_progress_events_total Progress events received.
+# TYPE bep_progress_events_total counter
+bep_progress_events_total 1
+`), "bep_progress_events_total"); err != nil {
+			t.Errorf("unexpected metric output: %v", err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// checkpointPath returns the sidecar file streamReader's progress through
+// filename is persisted to, so -f --resume can pick up where a previous
+// invocation left off.
+func checkpointPath(filename string) string {
+	return filename + ".bep-offset"
+}
+
+// readCheckpoint reads the byte offset left by a previous run. A missing
+// checkpoint file is not an error: it just means start from the beginning.
+func readCheckpoint(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint in %s: %w", path, err)
+	}
+	return offset, nil
+}
+
+// writeCheckpoint persists offset to path so a future -resume run can seek
+// past everything already decoded.
+func writeCheckpoint(path string, offset int64) error {
+	return os.WriteFile(path, []byte(strconv.FormatInt(offset, 10)), 0644)
+}
+
+// validateResumeOffset checks a checkpoint offset against the current
+// file's size before streamBEP seeks to it. A stale checkpoint left over
+// from a file that was since truncated or rotated out from under it (or
+// one orphaned by a crash between checkRotation resetting the in-memory
+// offset and the next checkpoint write) would otherwise seek past EOF and
+// leave follow mode waiting forever for bytes the file will never reach.
+func validateResumeOffset(offset, size int64) int64 {
+	if offset > size {
+		return 0
+	}
+	return offset
+}
+
+// fileIdentity returns a file's inode number, when the platform's
+// os.FileInfo exposes one, so streamReader can tell a rotated-in file (same
+// path, different inode) apart from the one it has open.
+func fileIdentity(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Ino), true
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Run("missing checkpoint reads as offset 0", func(t *testing.T) {
+		offset, err := readCheckpoint(filepath.Join(t.TempDir(), "does-not-exist.bep-offset"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if offset != 0 {
+			t.Errorf("offset = %d, want 0", offset)
+		}
+	})
+
+	t.Run("round-trips a written offset", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bep.bin.bep-offset")
+
+		if err := writeCheckpoint(path, 12345); err != nil {
+			t.Fatalf("writeCheckpoint failed: %v", err)
+		}
+
+		offset, err := readCheckpoint(path)
+		if err != nil {
+			t.Fatalf("readCheckpoint failed: %v", err)
+		}
+		if offset != 12345 {
+			t.Errorf("offset = %d, want 12345", offset)
+		}
+	})
+
+	t.Run("rejects a corrupt checkpoint", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bep.bin.bep-offset")
+		if err := os.WriteFile(path, []byte("not-a-number"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := readCheckpoint(path); err == nil {
+			t.Error("expected an error for a corrupt checkpoint")
+		}
+	})
+}
+
+func TestValidateResumeOffset(t *testing.T) {
+	t.Run("offset within the file is kept", func(t *testing.T) {
+		if got := validateResumeOffset(50, 100); got != 50 {
+			t.Errorf("validateResumeOffset(50, 100) = %d, want 50", got)
+		}
+	})
+
+	t.Run("offset at exactly EOF is kept", func(t *testing.T) {
+		if got := validateResumeOffset(100, 100); got != 100 {
+			t.Errorf("validateResumeOffset(100, 100) = %d, want 100", got)
+		}
+	})
+
+	t.Run("offset past the end of a shrunk file falls back to 0", func(t *testing.T) {
+		if got := validateResumeOffset(500, 100); got != 0 {
+			t.Errorf("validateResumeOffset(500, 100) = %d, want 0", got)
+		}
+	})
+}
+
+func TestFileIdentity(t *testing.T) {
+	t.Run("same file reports the same identity", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bep.bin")
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id1, ok1 := fileIdentity(info)
+		id2, ok2 := fileIdentity(info)
+		if ok1 != ok2 || id1 != id2 {
+			t.Errorf("fileIdentity not stable across calls: (%v,%v) vs (%v,%v)", id1, ok1, id2, ok2)
+		}
+	})
+}
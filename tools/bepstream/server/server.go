@@ -0,0 +1,91 @@
+// Package server implements Bazel's PublishBuildEventService, so bepstream
+// can be pointed at directly with --bes_backend=grpc://host:port instead of
+// reading a build event binary file from disk.
+package server
+
+import (
+	"context"
+	"io"
+
+	buildv1 "google.golang.org/genproto/googleapis/devtools/build/v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+// EventHandler is invoked with each decoded BuildEvent as it arrives over
+// the stream. It is called from the goroutine servicing the RPC, so the
+// caller is responsible for any synchronization it needs.
+type EventHandler func(event *bespb.BuildEvent)
+
+// NewEventHandler is called once per PublishBuildToolEventStream RPC (i.e.
+// once per Bazel invocation) to produce a fresh EventHandler for that
+// stream, so state like buildStats isn't shared across concurrent or
+// sequential builds hitting the same Server.
+type NewEventHandler func() EventHandler
+
+// Server implements buildv1.PublishBuildEventServer on top of a
+// NewEventHandler, so the same processEvent/buildStats pipeline used by the
+// file reader can be reused for a gRPC transport, with one instance of that
+// state per stream instead of one shared across the server's lifetime.
+type Server struct {
+	buildv1.UnimplementedPublishBuildEventServer
+	newHandler NewEventHandler
+}
+
+// New returns a Server that calls newHandler once per stream and forwards
+// every decoded BuildEvent on that stream to the handler it returns.
+func New(newHandler NewEventHandler) *Server {
+	return &Server{newHandler: newHandler}
+}
+
+// PublishLifecycleEvent acknowledges invocation and build lifecycle events
+// (InvocationAttemptStarted/Finished, BuildEnqueued/Finished). bepstream has
+// no use for these beyond the ACK, so it just drops them.
+func (s *Server) PublishLifecycleEvent(ctx context.Context, req *buildv1.PublishLifecycleEventRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+// PublishBuildToolEventStream receives the stream of OrderedBuildEvents that
+// make up a single build, unwraps the BazelEvent Any payload into a
+// bespb.BuildEvent, hands it to a handler created fresh for this stream, and
+// ACKs with a matching sequence number so Bazel can advance its in-flight
+// window.
+func (s *Server) PublishBuildToolEventStream(stream buildv1.PublishBuildEvent_PublishBuildToolEventStreamServer) error {
+	handler := s.newHandler()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		obe := req.GetOrderedBuildEvent()
+		if obe == nil {
+			continue
+		}
+
+		if any := obe.GetEvent().GetBazelEvent(); any != nil {
+			event := &bespb.BuildEvent{}
+			if err := any.UnmarshalTo(event); err != nil {
+				return err
+			}
+			handler(event)
+		}
+
+		resp := &buildv1.PublishBuildToolEventStreamResponse{
+			StreamId:       obe.GetStreamId(),
+			SequenceNumber: obe.GetSequenceNumber(),
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	buildv1 "google.golang.org/genproto/googleapis/devtools/build/v1"
+
+	bespb "github.com/example/bep-demo/tools/bepstream/proto"
+)
+
+const bufSize = 1024 * 1024
+
+func dialServer(t *testing.T, srv *Server) (buildv1.PublishBuildEventClient, func()) {
+	t.Helper()
+
+	lis := bufconn.Listen(bufSize)
+	grpcServer := grpc.NewServer()
+	buildv1.RegisterPublishBuildEventServer(grpcServer, srv)
+	go grpcServer.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+
+	return buildv1.NewPublishBuildEventClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+func TestPublishBuildToolEventStream(t *testing.T) {
+	t.Run("forwards decoded events and ACKs sequence numbers", func(t *testing.T) {
+		var got []*bespb.BuildEvent
+		srv := New(func() EventHandler {
+			return func(event *bespb.BuildEvent) {
+				got = append(got, event)
+			}
+		})
+		client, closeFn := dialServer(t, srv)
+		defer closeFn()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.PublishBuildToolEventStream(ctx)
+		if err != nil {
+			t.Fatalf("failed to open stream: %v", err)
+		}
+
+		events := []*bespb.BuildEvent{
+			{Payload: &bespb.BuildEvent_Progress{Progress: &bespb.Progress{}}},
+			{LastMessage: true},
+		}
+
+		for i, event := range events {
+			any, err := anypb.New(event)
+			if err != nil {
+				t.Fatalf("failed to pack event %d: %v", i, err)
+			}
+			req := &buildv1.PublishBuildToolEventStreamRequest{
+				OrderedBuildEvent: &buildv1.OrderedBuildEvent{
+					StreamId:       &buildv1.StreamId{BuildId: "test-build"},
+					SequenceNumber: int64(i + 1),
+					Event:          &buildv1.BuildEvent{Event: &buildv1.BuildEvent_BazelEvent{BazelEvent: any}},
+				},
+			}
+			if err := stream.Send(req); err != nil {
+				t.Fatalf("failed to send event %d: %v", i, err)
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("failed to receive ACK %d: %v", i, err)
+			}
+			if resp.SequenceNumber != int64(i+1) {
+				t.Errorf("ACK sequence number = %d, want %d", resp.SequenceNumber, i+1)
+			}
+		}
+
+		if err := stream.CloseSend(); err != nil {
+			t.Fatalf("failed to close send side: %v", err)
+		}
+
+		if len(got) != len(events) {
+			t.Fatalf("handler received %d events, want %d", len(got), len(events))
+		}
+		for i, event := range events {
+			if !proto.Equal(got[i], event) {
+				t.Errorf("event %d = %v, want %v", i, got[i], event)
+			}
+		}
+	})
+
+	t.Run("each stream gets its own handler instance", func(t *testing.T) {
+		handlersCreated := 0
+		eventCounts := make([]int, 0, 2)
+
+		srv := New(func() EventHandler {
+			handlersCreated++
+			count := 0
+			return func(event *bespb.BuildEvent) {
+				count++
+				eventCounts = append(eventCounts, count)
+			}
+		})
+		client, closeFn := dialServer(t, srv)
+		defer closeFn()
+
+		sendOneEvent := func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			stream, err := client.PublishBuildToolEventStream(ctx)
+			if err != nil {
+				t.Fatalf("failed to open stream: %v", err)
+			}
+
+			any, err := anypb.New(&bespb.BuildEvent{LastMessage: true})
+			if err != nil {
+				t.Fatalf("failed to pack event: %v", err)
+			}
+			req := &buildv1.PublishBuildToolEventStreamRequest{
+				OrderedBuildEvent: &buildv1.OrderedBuildEvent{
+					StreamId:       &buildv1.StreamId{BuildId: "test-build"},
+					SequenceNumber: 1,
+					Event:          &buildv1.BuildEvent{Event: &buildv1.BuildEvent_BazelEvent{BazelEvent: any}},
+				},
+			}
+			if err := stream.Send(req); err != nil {
+				t.Fatalf("failed to send event: %v", err)
+			}
+			if _, err := stream.Recv(); err != nil {
+				t.Fatalf("failed to receive ACK: %v", err)
+			}
+			if err := stream.CloseSend(); err != nil {
+				t.Fatalf("failed to close send side: %v", err)
+			}
+		}
+
+		sendOneEvent()
+		sendOneEvent()
+
+		if handlersCreated != 2 {
+			t.Fatalf("handlersCreated = %d, want 2", handlersCreated)
+		}
+		for i, count := range eventCounts {
+			if count != 1 {
+				t.Errorf("eventCounts[%d] = %d, want 1 (each stream's handler should start fresh)", i, count)
+			}
+		}
+	})
+}